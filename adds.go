@@ -0,0 +1,132 @@
+package hello
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"time"
+)
+
+// addsResponse mirrors the subset of the NOAA Aviation Digital Data
+// Service (ADDS) dataserver XML response we care about, for
+// dataSource=metars requests.
+type addsResponse struct {
+	Data struct {
+		Metars []addsMetar `xml:"METAR"`
+	} `xml:"data"`
+}
+
+type addsMetar struct {
+	RawText             string   `xml:"raw_text"`
+	StationID           string   `xml:"station_id"`
+	ObservationTime     string   `xml:"observation_time"`
+	TempC               *float64 `xml:"temp_c"`
+	DewpointC           *float64 `xml:"dewpoint_c"`
+	WindDirDegrees      *int     `xml:"wind_dir_degrees"`
+	WindSpeedKt         *int     `xml:"wind_speed_kt"`
+	VisibilityStatuteMi *float64 `xml:"visibility_statute_mi"`
+	AltimInHg           *float64 `xml:"altim_in_hg"`
+	FlightCategory      string   `xml:"flight_category"`
+	SkyCondition        []struct {
+		SkyCover       string `xml:"sky_cover,attr"`
+		CloudBaseFtAgl int    `xml:"cloud_base_ft_agl,attr"`
+	} `xml:"sky_condition"`
+}
+
+// ParseADDSXML decodes a NOAA ADDS dataserver_current response (as served
+// for dataSource=metars) into a Metar per returned station. Each report's
+// raw_text is run through the regular expression parser first to recover
+// fields the structured tags don't carry (weather phenomena, remarks-era
+// cloud layers), then the structured XML fields overwrite the numeric
+// fields they cover, since those are authoritative when present.
+func ParseADDSXML(r io.Reader) ([]Metar, error) {
+	var resp addsResponse
+	if err := xml.NewDecoder(r).Decode(&resp); err != nil {
+		return nil, err
+	}
+
+	metars := make([]Metar, 0, len(resp.Data.Metars))
+	for _, am := range resp.Data.Metars {
+		m := Metar{}
+
+		obsTime, err := time.Parse(time.RFC3339, am.ObservationTime)
+		if am.RawText != "" {
+			anchor := obsTime
+			if err != nil || obsTime.IsZero() {
+				anchor = time.Now().UTC()
+			}
+			m.Parse(am.RawText, anchor.Format(dateFormat))
+		}
+
+		if am.StationID != "" {
+			m.Station = am.StationID
+		}
+		if err == nil && !obsTime.IsZero() {
+			m.Time = obsTime
+		}
+		if am.TempC != nil {
+			m.Temp = Temperature{Value: *am.TempC, Unit: C}
+		}
+		if am.DewpointC != nil {
+			m.DewPt = Temperature{Value: *am.DewpointC, Unit: C}
+		}
+		if am.WindDirDegrees != nil {
+			m.Wind.Dir = *am.WindDirDegrees
+		}
+		if am.WindSpeedKt != nil {
+			m.Wind.Spd = WindSpeed{Value: float64(*am.WindSpeedKt), Unit: KT}
+		}
+		if am.VisibilityStatuteMi != nil {
+			m.Visibility = Visibility{Value: *am.VisibilityStatuteMi, Unit: SM}
+		}
+		if am.AltimInHg != nil {
+			m.Pressure = Pressure{Value: *am.AltimInHg, Unit: INHG}
+		}
+		if len(am.SkyCondition) > 0 {
+			m.Sky = nil
+			for _, sc := range am.SkyCondition {
+				m.Sky = append(m.Sky, Sky{Cover: sc.SkyCover, Height: sc.CloudBaseFtAgl / 100})
+			}
+		}
+
+		m.FlightCategory = am.FlightCategory
+		if m.FlightCategory == "" {
+			m.FlightCategory = deriveFlightCategory(m)
+		}
+
+		metars = append(metars, m)
+	}
+
+	return metars, nil
+}
+
+// deriveFlightCategory computes the standard VFR/MVFR/IFR/LIFR category
+// from ceiling (lowest BKN/OVC/VV layer) and visibility, for reports that
+// don't carry a flight_category tag of their own. A missing ceiling or
+// visibility is treated as unlimited rather than zero, so that a report
+// with sky or visibility data absent isn't misclassified LIFR.
+func deriveFlightCategory(m Metar) string {
+	ceilingFt := math.MaxInt32
+	for _, sky := range m.Sky {
+		if sky.Cover == "BKN" || sky.Cover == "OVC" || sky.Cover == "VV" {
+			if ft := sky.Height * 100; ft < ceilingFt {
+				ceilingFt = ft
+			}
+		}
+	}
+	visSM := math.MaxFloat64
+	if m.Visibility.Value > 0 {
+		visSM = m.Visibility.In(SM).Value
+	}
+
+	switch {
+	case ceilingFt < 500 || visSM < 1:
+		return "LIFR"
+	case ceilingFt < 1000 || visSM < 3:
+		return "IFR"
+	case ceilingFt <= 3000 || visSM <= 5:
+		return "MVFR"
+	default:
+		return "VFR"
+	}
+}
@@ -0,0 +1,29 @@
+package hello
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseADDSXMLFallsBackToRawTextWithoutObservationTime(t *testing.T) {
+	xmlData := `<response><data><METAR>
+<raw_text>KBOS 271851Z 14008KT 10SM -RA FEW030 22/18 A3000</raw_text>
+<station_id>KBOS</station_id>
+</METAR></data></response>`
+
+	metars, err := ParseADDSXML(strings.NewReader(xmlData))
+	if err != nil {
+		t.Fatalf("ParseADDSXML() error = %v", err)
+	}
+	if len(metars) != 1 {
+		t.Fatalf("got %d metars, want 1", len(metars))
+	}
+
+	m := metars[0]
+	if len(m.Weather) != 1 || m.Weather[0].Precip != "RA" {
+		t.Fatalf("got weather %+v, want a single RA entry from the raw_text fallback", m.Weather)
+	}
+	if len(m.Sky) != 1 || m.Sky[0].Cover != "FEW" {
+		t.Fatalf("got sky %+v, want a single FEW layer from the raw_text fallback", m.Sky)
+	}
+}
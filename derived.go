@@ -0,0 +1,51 @@
+package hello
+
+import "math"
+
+// RelativeHumidity returns the relative humidity in percent, derived from
+// temperature and dew point via the Magnus formula.
+func (m *Metar) RelativeHumidity() float64 {
+	t, td := m.Temp.In(C).Value, m.DewPt.In(C).Value
+	return 100 * math.Exp((17.625*td)/(243.04+td)) / math.Exp((17.625*t)/(243.04+t))
+}
+
+// WindChillC returns the NWS wind chill in Celsius. It is only defined
+// for temperatures at or below 10C and wind speeds at or above 4.8 km/h;
+// outside that range the air temperature itself is returned unchanged.
+func (m *Metar) WindChillC() float64 {
+	t := m.Temp.In(C).Value
+	v := m.Wind.Spd.In(KMH).Value
+	if t > 10 || v < 4.8 {
+		return t
+	}
+	return 13.12 + 0.6215*t - 11.37*math.Pow(v, 0.16) + 0.3965*t*math.Pow(v, 0.16)
+}
+
+// HeatIndexC returns the heat index in Celsius via the Rothfusz
+// regression, which operates on Fahrenheit inputs and is only valid for
+// temperatures at or above 80F with relative humidity at or above 40%;
+// outside that range the air temperature itself is returned unchanged.
+func (m *Metar) HeatIndexC() float64 {
+	t := m.Temp.In(C).Value
+	tf := t*9/5 + 32
+	rh := m.RelativeHumidity()
+	if tf < 80 || rh < 40 {
+		return t
+	}
+
+	hiF := -42.379 + 2.04901523*tf + 10.14333127*rh -
+		0.22475541*tf*rh - 0.00683783*tf*tf - 0.05481717*rh*rh +
+		0.00122874*tf*tf*rh + 0.00085282*tf*rh*rh - 0.00000199*tf*tf*rh*rh
+
+	return (hiF - 32) * 5 / 9
+}
+
+// DensityAltitudeFt returns the density altitude in feet for an airfield
+// at elevationFt, derived from the reported altimeter setting and
+// temperature.
+func (m *Metar) DensityAltitudeFt(elevationFt int) float64 {
+	altimIn := m.Pressure.In(INHG).Value
+	pressureAlt := (29.92-altimIn)*1000 + float64(elevationFt)
+	isa := 15 - 2*(float64(elevationFt)/1000)
+	return pressureAlt + 120*(m.Temp.In(C).Value-isa)
+}
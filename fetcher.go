@@ -0,0 +1,134 @@
+package hello
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// cacheTTL is how long a cached Metar is served before being refetched.
+// METARs are issued roughly once an hour, so anything newer than this is
+// still the latest available observation.
+const cacheTTL = 55 * time.Minute
+
+type cacheEntry struct {
+	metar *Metar
+}
+
+// Fetcher fetches METARs for a set of stations from NOAA, fanning the
+// requests out across a bounded worker pool and caching results keyed by
+// station so that repeated requests within the same hour don't refetch.
+type Fetcher struct {
+	// Limit bounds the number of concurrent NOAA fetches. Zero means
+	// GOMAXPROCS.
+	Limit int
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+// NewFetcher returns a ready to use Fetcher.
+func NewFetcher() *Fetcher {
+	return &Fetcher{cache: map[string]cacheEntry{}}
+}
+
+// Fetch retrieves the current METAR for each of stations, using client to
+// reach NOAA. It returns the parsed Metar for every station that
+// succeeded and the error for every station that didn't; a station will
+// appear in exactly one of the two maps.
+func (f *Fetcher) Fetch(client *http.Client, stations []string) (map[string]*Metar, map[string]error) {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = runtime.GOMAXPROCS(0)
+	}
+	if limit > len(stations) {
+		limit = len(stations)
+	}
+
+	type result struct {
+		station string
+		metar   *Metar
+		err     error
+	}
+
+	jobs := make(chan string)
+	results := make(chan result)
+
+	var wg sync.WaitGroup
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for station := range jobs {
+				m, err := f.fetchStation(client, station)
+				results <- result{station, m, err}
+			}
+		}()
+	}
+
+	go func() {
+		for _, station := range stations {
+			jobs <- station
+		}
+		close(jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	metars := map[string]*Metar{}
+	errs := map[string]error{}
+	for r := range results {
+		if r.err != nil {
+			errs[r.station] = r.err
+		} else {
+			metars[r.station] = r.metar
+		}
+	}
+
+	return metars, errs
+}
+
+func (f *Fetcher) fetchStation(client *http.Client, station string) (*Metar, error) {
+	f.mu.Lock()
+	entry, cached := f.cache[station]
+	f.mu.Unlock()
+	if cached && time.Since(entry.metar.Time) < cacheTTL {
+		return entry.metar, nil
+	}
+
+	url := fmt.Sprintf("http://tgftp.nws.noaa.gov/data/observations/metar/stations/%s.TXT", station)
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	br := bufio.NewReader(resp.Body)
+	line, _, err := br.ReadLine()
+	if err != nil {
+		return nil, fmt.Errorf("Error reading date for station %s", station)
+	}
+	rawDate := string(line)
+	line, _, err = br.ReadLine()
+	if err != nil {
+		return nil, fmt.Errorf("Error reading metar for station %s", station)
+	}
+	rawMetar := string(line)
+
+	m := &Metar{}
+	if err := m.Parse(rawMetar, rawDate); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	f.cache[station] = cacheEntry{metar: m}
+	f.mu.Unlock()
+
+	return m, nil
+}
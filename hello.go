@@ -2,14 +2,16 @@ package hello
 
 import (
 	"bufio"
-        "fmt"
-        "net/http"
-        "regexp"
-        "time"
-        "strconv"
-
-        "google.golang.org/appengine"
-        "google.golang.org/appengine/urlfetch"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/appengine"
+	"google.golang.org/appengine/urlfetch"
 )
 
 const dateFormat = "2006/01/02 15:04"
@@ -24,13 +26,14 @@ var parserStrings map[string]string = map[string]string{"type": `^(?P<type>METAR
 	"weather":    `(?P<int>(-|\+|VC)+)?(?P<desc>(MI|PR|BC|DR|BL|SH|TS|FZ)+)?(:?(?P<prec>(DZ|RA|SN|SG|IC|PL|GR|GS|UP)+)(?P<obsc>BR|FG|FU|VA|DU|SA|HZ|PY)?(?P<other>PO|SQ|FC|SS|DS)?|(?P<obsc>BR|FG|FU|VA|DU|SA|HZ|PY)(?P<other>PO|SQ|FC|SS|DS)?|(?P<other>PO|SQ|FC|SS|DS))+\s+`,
 	"sky":        `(?P<cover>VV|CLR|SKC|SCK|NSC|NCD|BKN|SCT|FEW|OVC)(?P<height>\d{2,4})?(?P<cloud>([A-Z][A-Z]+))?\s+`,
 	"temp":       `^(?P<temp>(M|-)?\d+|//|XX|MM)/(?P<dewpt>(M|-)?\d+|//|XX|MM)?\s+`,
-	"press":      `^(?P<unit>A|Q|QNH|SLP)?(?P<press>\d{3,4}|////)(?P<unit2>INS)?\s*`}
+	"press":      `^(?P<unit>A|Q|QNH|SLP)?(?P<press>\d{3,4}|////)(?P<unit2>INS)?\s*`,
+	"rmk":        `^RMK\s+`}
 
 type Wind struct {
 	Vrb     bool
 	Dir     int
-	Spd     int
-	Gust    int
+	Spd     WindSpeed
+	Gust    WindSpeed
 	VarFrom int
 	VarTo   int
 }
@@ -53,12 +56,18 @@ type Metar struct {
 	Time       time.Time
 	Mod        string
 	Wind       Wind
-	Visibility int
+	Visibility Visibility
 	Weather    []Weather
 	Sky        []Sky
-	Temp       int
-	DewPt      int
-	Pressure   int
+	Temp       Temperature
+	DewPt      Temperature
+	Pressure   Pressure
+
+	// FlightCategory is VFR, MVFR, IFR or LIFR, either taken verbatim from
+	// a structured data source or derived from ceiling and visibility.
+	FlightCategory string
+
+	Remarks Remarks
 }
 
 func (m *Metar) Parse(rawMetar, rawDate string) error {
@@ -114,6 +123,11 @@ func (m *Metar) Parse(rawMetar, rawDate string) error {
 		return fmt.Errorf("Error parsing metar wind")
 	}
 
+	windUnit := KT
+	if idx[10] != -1 && idx[11] != -1 {
+		windUnit = Unit(rawMetar[idx[10]:idx[11]])
+	}
+
 	wind := Wind{}
 	if idx[2] != -1 && idx[3] != -1 {
 		if rawMetar[idx[2]:idx[3]] == "VRB" {
@@ -128,7 +142,7 @@ func (m *Metar) Parse(rawMetar, rawDate string) error {
 	}
 	if idx[4] != -1 && idx[5] != -1 {
 		if wspd, err := strconv.Atoi(rawMetar[idx[4]:idx[5]]); err == nil {
-			wind.Spd = wspd
+			wind.Spd = WindSpeed{Value: float64(wspd), Unit: windUnit}
 		} else {
 			return fmt.Errorf("Error converting wind speed in metar")
 		}
@@ -136,7 +150,7 @@ func (m *Metar) Parse(rawMetar, rawDate string) error {
 
 	if idx[8] != -1 && idx[9] != -1 {
 		if gust, err := strconv.Atoi(rawMetar[idx[8]:idx[9]]); err == nil {
-			wind.Gust = gust
+			wind.Gust = WindSpeed{Value: float64(gust), Unit: windUnit}
 		} else {
 			return fmt.Errorf("Error converting gust speed in metar")
 		}
@@ -161,14 +175,39 @@ func (m *Metar) Parse(rawMetar, rawDate string) error {
 	rawMetar = rawMetar[idx[1]:]
 
 	idx = parsers["visibility"].FindStringSubmatchIndex(rawMetar)
-	if idx == nil || idx[4] == -1 || idx[5] == -1 {
+	if idx == nil {
 		return fmt.Errorf("Error parsing metar visibility")
 	}
 
-	if vis, err := strconv.Atoi(rawMetar[idx[4]:idx[5]]); err == nil {
-		m.Visibility = vis
-	} else {
-		return fmt.Errorf("Error converting visibility value in metar")
+	switch {
+	case idx[4] != -1 && idx[5] != -1:
+		distStr := rawMetar[idx[4]:idx[5]]
+		qualifier := ""
+		if idx[6] != -1 && idx[7] != -1 {
+			qualifier = rawMetar[idx[6]:idx[7]]
+			distStr = distStr[len(qualifier):]
+		}
+		if vis, err := strconv.Atoi(distStr); err == nil {
+			m.Visibility = Visibility{Value: float64(vis), Unit: M, Qualifier: qualifier}
+		} else {
+			return fmt.Errorf("Error converting visibility value in metar")
+		}
+	case idx[10] != -1 && idx[11] != -1:
+		qualifier := ""
+		if idx[12] != -1 && idx[13] != -1 {
+			qualifier = rawMetar[idx[12]:idx[13]]
+		}
+		if vis, err := strconv.Atoi(rawMetar[idx[14]:idx[15]]); err == nil {
+			unit := SM
+			if idx[16] != -1 && idx[17] != -1 {
+				unit = Unit(rawMetar[idx[16]:idx[17]])
+			}
+			m.Visibility = Visibility{Value: float64(vis), Unit: unit, Qualifier: qualifier}
+		} else {
+			return fmt.Errorf("Error converting visibility value in metar")
+		}
+	default:
+		return fmt.Errorf("Error parsing metar visibility")
 	}
 	rawMetar = rawMetar[idx[1]:]
 
@@ -227,7 +266,7 @@ func (m *Metar) Parse(rawMetar, rawDate string) error {
 		tempStr = "-" + tempStr[1:]
 	}
 	if temp, err := strconv.Atoi(tempStr); err == nil {
-		m.Temp = temp
+		m.Temp = Temperature{Value: float64(temp), Unit: C}
 	} else {
 		return fmt.Errorf("Error converting temperature value in metar")
 	}
@@ -237,7 +276,7 @@ func (m *Metar) Parse(rawMetar, rawDate string) error {
 		dewPtStr = "-" + dewPtStr[1:]
 	}
 	if dewPt, err := strconv.Atoi(dewPtStr); err == nil {
-		m.DewPt = dewPt
+		m.DewPt = Temperature{Value: float64(dewPt), Unit: C}
 	} else {
 		return fmt.Errorf("Error converting dew point value in metar")
 	}
@@ -247,49 +286,161 @@ func (m *Metar) Parse(rawMetar, rawDate string) error {
 	if idx == nil {
 		return fmt.Errorf("Error parsing metar pressure")
 	}
-	if idx[2] != -1 && idx[3] != -1 && rawMetar[idx[2]:idx[3]] == "Q" {
+
+	var pressErr error
+	switch {
+	case idx[2] != -1 && idx[3] != -1 && rawMetar[idx[2]:idx[3]] == "Q":
 		if value, err := strconv.Atoi(rawMetar[idx[4]:idx[5]]); err == nil {
-			m.Pressure = value
+			m.Pressure = Pressure{Value: float64(value), Unit: HPA}
 		} else {
-			return fmt.Errorf("Error converting pressure value in metar")
+			pressErr = fmt.Errorf("Error converting pressure value in metar")
 		}
-	} else {
-		return fmt.Errorf("Error interpreting metar pressure value")
+	case idx[2] != -1 && idx[3] != -1 && rawMetar[idx[2]:idx[3]] == "A":
+		if value, err := strconv.Atoi(rawMetar[idx[4]:idx[5]]); err == nil {
+			m.Pressure = Pressure{Value: float64(value) / 100, Unit: INHG}
+		} else {
+			pressErr = fmt.Errorf("Error converting pressure value in metar")
+		}
+	default:
+		pressErr = fmt.Errorf("Error interpreting metar pressure value")
+	}
+	rawMetar = rawMetar[idx[1]:]
+
+	// Remarks are parsed even when the pressure group itself failed to
+	// decode, so a malformed altimeter doesn't also discard the RMK
+	// section's AO1/AO2/SLP/T-group data.
+	if idx := parsers["rmk"].FindStringIndex(rawMetar); idx != nil {
+		m.Remarks = parseRemarks(rawMetar[idx[1]:])
 	}
 
-	return nil
+	return pressErr
 }
+
+var fetcher = NewFetcher()
+
 func init() {
 	http.HandleFunc("/", handler)
+	http.HandleFunc("/metar", metarsHandler)
+}
+
+// metarsHandler serves /metar?stations=YSSY,KSEA,EGLL, fetching each
+// station concurrently through fetcher and returning the results as a
+// JSON array.
+func metarsHandler(w http.ResponseWriter, r *http.Request) {
+	stationsParam := r.URL.Query().Get("stations")
+	if stationsParam == "" {
+		http.Error(w, "missing stations parameter", 400)
+		return
+	}
+
+	rawStations := strings.Split(stationsParam, ",")
+	stations := make([]string, 0, len(rawStations))
+	for _, s := range rawStations {
+		if s = strings.ToUpper(strings.TrimSpace(s)); s != "" {
+			stations = append(stations, s)
+		}
+	}
+
+	elevationFt, _ := strconv.Atoi(r.URL.Query().Get("elevft"))
+
+	ctx := appengine.NewContext(r)
+	client := urlfetch.Client(ctx)
+
+	metars, errs := fetcher.Fetch(client, stations)
+
+	// derivedMetar embeds Metar so the JSON output carries the
+	// meteorology clients would otherwise have to rederive themselves.
+	type derivedMetar struct {
+		*Metar
+		RelativeHumidity  float64 `json:"relative_humidity"`
+		WindChillC        float64 `json:"wind_chill_c"`
+		HeatIndexC        float64 `json:"heat_index_c"`
+		DensityAltitudeFt float64 `json:"density_altitude_ft"`
+	}
+
+	type stationResult struct {
+		Station string        `json:"station"`
+		Metar   *derivedMetar `json:"metar,omitempty"`
+		Error   string        `json:"error,omitempty"`
+	}
+	results := make([]stationResult, 0, len(stations))
+	for _, station := range stations {
+		if m, ok := metars[station]; ok {
+			results = append(results, stationResult{Station: station, Metar: &derivedMetar{
+				Metar:             m,
+				RelativeHumidity:  m.RelativeHumidity(),
+				WindChillC:        m.WindChillC(),
+				HeatIndexC:        m.HeatIndexC(),
+				DensityAltitudeFt: m.DensityAltitudeFt(elevationFt),
+			}})
+		} else {
+			results = append(results, stationResult{Station: station, Error: errs[station].Error()})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
 }
 
 func handler(w http.ResponseWriter, r *http.Request) {
-	url := "http://tgftp.nws.noaa.gov/data/observations/metar/stations/YSSY.TXT"
-        ctx := appengine.NewContext(r)
-        client := urlfetch.Client(ctx)
-        resp, err := client.Get(url)
-        if err != nil {
-                http.Error(w, fmt.Sprintf("Err 0: %v", err), 400)
-                return
-        }
-        defer resp.Body.Close()
-        br := bufio.NewReader(resp.Body)
-        line, _, err := br.ReadLine()
-        if err != nil {
-                http.Error(w, "Err 1", 400)
-                return
-        }
-        rawDate := string(line)
-        line, _, err = br.ReadLine()
-        if err != nil {
-                http.Error(w, "Err 2", 400)
-                return
-        }
-        rawMetar := string(line)
-
-        m := &Metar{}
-	err = m.Parse(rawMetar, rawDate)
+	reportType := strings.ToUpper(r.URL.Query().Get("type"))
+
+	var url string
+	switch reportType {
+	case "TAF":
+		url = "http://tgftp.nws.noaa.gov/data/forecasts/taf/stations/YSSY.TXT"
+	default:
+		reportType = "METAR"
+		url = "http://tgftp.nws.noaa.gov/data/observations/metar/stations/YSSY.TXT"
+	}
+
+	ctx := appengine.NewContext(r)
+	client := urlfetch.Client(ctx)
+	resp, err := client.Get(url)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Err 0: %v", err), 400)
+		return
+	}
+	defer resp.Body.Close()
+	br := bufio.NewReader(resp.Body)
+	line, _, err := br.ReadLine()
+	if err != nil {
+		http.Error(w, "Err 1", 400)
+		return
+	}
+	rawDate := string(line)
+	line, _, err = br.ReadLine()
+	if err != nil {
+		http.Error(w, "Err 2", 400)
+		return
+	}
+	rawReport := string(line)
+
+	if reportType == "TAF" {
+		// TAF reports wrap their FM/BECMG/TEMPO/PROB30/PROB40 groups
+		// across continuation lines, so read the rest of the body and
+		// join it with the first line before parsing.
+		var body strings.Builder
+		body.WriteString(rawReport)
+		for {
+			line, _, err := br.ReadLine()
+			if err != nil {
+				break
+			}
+			body.WriteString(" ")
+			body.WriteString(string(line))
+		}
+		rawReport = body.String()
+
+		t := &TAF{}
+		err = t.Parse(rawReport, rawDate)
+		fmt.Fprint(w, fmt.Sprintf("Date: %s Taf: %v Error: %v", rawDate, t, err))
+		return
+	}
+
+	m := &Metar{}
+	err = m.Parse(rawReport, rawDate)
 
 	fmt.Fprint(w, fmt.Sprintf("Date: %s Metar: %v Error: %v", rawDate, m, err))
-        //fmt.Fprint(w, fmt.Sprintf("Date: %s Metar: %s", rawDate, rawMetar))
+	//fmt.Fprint(w, fmt.Sprintf("Date: %s Metar: %s", rawDate, rawMetar))
 }
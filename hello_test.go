@@ -0,0 +1,61 @@
+package hello
+
+import "testing"
+
+func TestParseVisibilityPrefix(t *testing.T) {
+	cases := []struct {
+		name      string
+		raw       string
+		wantValue float64
+		wantUnit  Unit
+		wantQual  string
+	}{
+		{"greater than prefix", "KBOS 271851Z 14008KT P6SM FEW250 22/18 Q1013", 6, SM, "P"},
+		{"no prefix", "KBOS 271851Z 14008KT 10SM FEW250 22/18 Q1013", 10, SM, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := &Metar{}
+			if err := m.Parse(c.raw, "2016/07/27 18:51"); err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if m.Visibility.Value != c.wantValue || m.Visibility.Unit != c.wantUnit || m.Visibility.Qualifier != c.wantQual {
+				t.Fatalf("got %+v, want {Value:%v Unit:%v Qualifier:%q}", m.Visibility, c.wantValue, c.wantUnit, c.wantQual)
+			}
+		})
+	}
+}
+
+func TestParseAltimeter(t *testing.T) {
+	cases := []struct {
+		name      string
+		raw       string
+		wantValue float64
+		wantUnit  Unit
+	}{
+		{"inHg altimeter", "KSEA 271853Z 21006KT 10SM FEW250 22/12 A3012", 30.12, INHG},
+		{"hPa altimeter", "EGLL 271850Z 21006KT 10SM FEW250 22/12 Q1013", 1013, HPA},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := &Metar{}
+			if err := m.Parse(c.raw, "2016/07/27 18:53"); err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if m.Pressure.Value != c.wantValue || m.Pressure.Unit != c.wantUnit {
+				t.Fatalf("got %+v, want {Value:%v Unit:%v}", m.Pressure, c.wantValue, c.wantUnit)
+			}
+		})
+	}
+}
+
+func TestParseRemarksSurviveBadPressure(t *testing.T) {
+	raw := "KSEA 271853Z 21006KT 10SM FEW250 22/12 SLP132 RMK AO2 SLP132 T01170089"
+	m := &Metar{}
+	if err := m.Parse(raw, "2016/07/27 18:53"); err == nil {
+		t.Fatalf("Parse() error = nil, want a pressure error")
+	}
+	if m.Remarks.AutoStationType != "AO2" {
+		t.Fatalf("got remarks %+v, want AutoStationType AO2 despite bad pressure group", m.Remarks)
+	}
+}
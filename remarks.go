@@ -0,0 +1,193 @@
+package hello
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var remarksParserStrings = map[string]string{
+	"auto":     `^(?P<type>AO1|AO2)\s+`,
+	"slp":      `^SLP(?P<val>\d{3})\s+`,
+	"temp":     `^T(?P<tsign>0|1)(?P<temp>\d{3})((?P<dsign>0|1)(?P<dewpt>\d{3}))?\s+`,
+	"sensor":   `^(?P<flag>PNO|RVRNO|FZRANO)\s+`,
+	"pressure": `^(?P<flag>PRESRR|PRESFR)\s+`,
+	"precip6":  `^6(?P<val>\d{4})\s+`,
+	"precip24": `^7(?P<val>\d{4})\s+`,
+	"pkwnd":    `^PK\s+WND\s+(?P<dir>\d{3})(?P<spd>\d{2,3})/(?P<hour>\d\d)(?P<min>\d\d)\s+`,
+}
+
+// Remarks holds the subset of the free-form METAR RMK section we know
+// how to decode. Tokens that aren't recognised are skipped rather than
+// treated as an error, since the remarks section is the least
+// standardised part of a report.
+type Remarks struct {
+	// AutoStationType is AO1 (no precipitation discriminator) or AO2
+	// (with precipitation discriminator), for automated stations.
+	AutoStationType string
+
+	// SeaLevelPressure is the SLP group, in hPa.
+	SeaLevelPressure *float64
+
+	// PreciseTemp and PreciseDewPt are the Txxxxxxxx group, in degrees
+	// Celsius decoded from the group's tenths of a degree.
+	PreciseTemp  *float64
+	PreciseDewPt *float64
+
+	// PrecipSinceLastOb and Precip24Hour are the 6xxxx and 7xxxx groups,
+	// in inches decoded from the group's hundredths of an inch.
+	PrecipSinceLastOb *float64
+	Precip24Hour      *float64
+
+	// PressureRisingRapidly and PressureFallingRapidly are the PRESRR
+	// and PRESFR groups.
+	PressureRisingRapidly  bool
+	PressureFallingRapidly bool
+
+	// SensorPNO, SensorRVRNO and SensorFZRANO are sensor status flags:
+	// precipitation amount not available, runway visual range not
+	// available, and freezing rain sensor not available.
+	SensorPNO    bool
+	SensorRVRNO  bool
+	SensorFZRANO bool
+
+	// PeakWind is the PK WND group, nil if the remarks had none.
+	PeakWind *PeakWind
+}
+
+// PeakWind is the peak wind direction and speed observed since the last
+// METAR, and the Zulu hour/minute it occurred.
+type PeakWind struct {
+	Dir  int
+	Spd  int
+	Hour int
+	Min  int
+}
+
+// parseRemarks decodes the tokens of a METAR's RMK section into a
+// Remarks. rawRemarks is the text following the RMK keyword.
+func parseRemarks(rawRemarks string) Remarks {
+	parsers := map[string]*regexp.Regexp{}
+	for key, value := range remarksParserStrings {
+		parsers[key] = regexp.MustCompile(value)
+	}
+
+	// Every remarks token pattern below requires trailing whitespace to
+	// mark its end, so guarantee one is present even when rawRemarks is
+	// a line with no trailing space (the common case for the last token).
+	if !strings.HasSuffix(rawRemarks, " ") {
+		rawRemarks += " "
+	}
+
+	r := Remarks{}
+	for len(rawRemarks) > 0 {
+		if idx := parsers["auto"].FindStringSubmatchIndex(rawRemarks); idx != nil {
+			r.AutoStationType = rawRemarks[idx[2]:idx[3]]
+			rawRemarks = rawRemarks[idx[1]:]
+			continue
+		}
+
+		if idx := parsers["slp"].FindStringSubmatchIndex(rawRemarks); idx != nil {
+			if tenths, err := strconv.Atoi(rawRemarks[idx[2]:idx[3]]); err == nil {
+				slp := slpFromTenths(tenths)
+				r.SeaLevelPressure = &slp
+			}
+			rawRemarks = rawRemarks[idx[1]:]
+			continue
+		}
+
+		if idx := parsers["temp"].FindStringSubmatchIndex(rawRemarks); idx != nil {
+			if temp, err := strconv.Atoi(rawRemarks[idx[4]:idx[5]]); err == nil {
+				v := float64(temp) / 10
+				if rawRemarks[idx[2]:idx[3]] == "1" {
+					v = -v
+				}
+				r.PreciseTemp = &v
+			}
+			if idx[10] != -1 && idx[11] != -1 {
+				if dewpt, err := strconv.Atoi(rawRemarks[idx[10]:idx[11]]); err == nil {
+					v := float64(dewpt) / 10
+					if rawRemarks[idx[8]:idx[9]] == "1" {
+						v = -v
+					}
+					r.PreciseDewPt = &v
+				}
+			}
+			rawRemarks = rawRemarks[idx[1]:]
+			continue
+		}
+
+		if idx := parsers["sensor"].FindStringSubmatchIndex(rawRemarks); idx != nil {
+			switch rawRemarks[idx[2]:idx[3]] {
+			case "PNO":
+				r.SensorPNO = true
+			case "RVRNO":
+				r.SensorRVRNO = true
+			case "FZRANO":
+				r.SensorFZRANO = true
+			}
+			rawRemarks = rawRemarks[idx[1]:]
+			continue
+		}
+
+		if idx := parsers["pressure"].FindStringSubmatchIndex(rawRemarks); idx != nil {
+			switch rawRemarks[idx[2]:idx[3]] {
+			case "PRESRR":
+				r.PressureRisingRapidly = true
+			case "PRESFR":
+				r.PressureFallingRapidly = true
+			}
+			rawRemarks = rawRemarks[idx[1]:]
+			continue
+		}
+
+		if idx := parsers["precip6"].FindStringSubmatchIndex(rawRemarks); idx != nil {
+			if val, err := strconv.Atoi(rawRemarks[idx[2]:idx[3]]); err == nil {
+				v := float64(val) / 100
+				r.PrecipSinceLastOb = &v
+			}
+			rawRemarks = rawRemarks[idx[1]:]
+			continue
+		}
+
+		if idx := parsers["precip24"].FindStringSubmatchIndex(rawRemarks); idx != nil {
+			if val, err := strconv.Atoi(rawRemarks[idx[2]:idx[3]]); err == nil {
+				v := float64(val) / 100
+				r.Precip24Hour = &v
+			}
+			rawRemarks = rawRemarks[idx[1]:]
+			continue
+		}
+
+		if idx := parsers["pkwnd"].FindStringSubmatchIndex(rawRemarks); idx != nil {
+			dir, dirErr := strconv.Atoi(rawRemarks[idx[2]:idx[3]])
+			spd, spdErr := strconv.Atoi(rawRemarks[idx[4]:idx[5]])
+			hour, hourErr := strconv.Atoi(rawRemarks[idx[6]:idx[7]])
+			min, minErr := strconv.Atoi(rawRemarks[idx[8]:idx[9]])
+			if dirErr == nil && spdErr == nil && hourErr == nil && minErr == nil {
+				r.PeakWind = &PeakWind{Dir: dir, Spd: spd, Hour: hour, Min: min}
+			}
+			rawRemarks = rawRemarks[idx[1]:]
+			continue
+		}
+
+		// Unrecognised token: skip past it and keep scanning.
+		if sp := strings.IndexByte(rawRemarks, ' '); sp != -1 {
+			rawRemarks = strings.TrimLeft(rawRemarks[sp+1:], " ")
+		} else {
+			break
+		}
+	}
+
+	return r
+}
+
+// slpFromTenths decodes a 3 digit SLP group into hPa: values of 500 and
+// above are assumed to be in the 900s (e.g. 965 -> 996.5), values below
+// that in the 1000s (e.g. 013 -> 1001.3), per the NWS/FMH-1 encoding rule.
+func slpFromTenths(tenths int) float64 {
+	if tenths >= 500 {
+		return 900 + float64(tenths)/10
+	}
+	return 1000 + float64(tenths)/10
+}
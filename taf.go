@@ -0,0 +1,299 @@
+package hello
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var tafParserStrings map[string]string = map[string]string{
+	"header":    `^TAF\s+(AMD\s+|COR\s+)?`,
+	"validity":  `^(?P<fday>\d\d)(?P<fhour>\d\d)/(?P<tday>\d\d)(?P<thour>\d\d)\s+`,
+	"fm":        `^FM(?P<day>\d\d)(?P<hour>\d\d)(?P<min>\d\d)\s+`,
+	"change":    `^(?P<change>BECMG|TEMPO|PROB30|PROB40)\s+(TEMPO\s+)?(?P<fday>\d\d)(?P<fhour>\d\d)/(?P<tday>\d\d)(?P<thour>\d\d)\s+`,
+	"nextgroup": `FM\d{6}|BECMG|TEMPO|PROB30|PROB40`,
+}
+
+// TAFGroup holds the conditions forecast for a single period of a TAF,
+// either the initial (unconditional) group or one introduced by a
+// FM/BECMG/TEMPO/PROB30/PROB40 change indicator.
+type TAFGroup struct {
+	Change     string
+	ValidFrom  time.Time
+	ValidTo    time.Time
+	Wind       Wind
+	Visibility Visibility
+	Weather    []Weather
+	Sky        []Sky
+}
+
+// TAF is a parsed Terminal Aerodrome Forecast report.
+type TAF struct {
+	Station   string
+	Issued    time.Time
+	ValidFrom time.Time
+	ValidTo   time.Time
+	Groups    []TAFGroup
+}
+
+// tafPeriod resolves a TAF's DDHH day/hour fields into a full time.Time,
+// rolling over into the following month when the day number wraps around
+// (e.g. a TAF issued on the 31st valid into the 1st).
+func tafPeriod(issued time.Time, day, hour int) time.Time {
+	year, month := issued.Year(), issued.Month()
+	if day < issued.Day()-7 {
+		month++
+		if month > time.December {
+			month = time.January
+			year++
+		}
+	}
+	return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+// parseTAFBody consumes the wind, visibility, weather and sky blocks of a
+// single TAF forecast group, in the order they appear in the report, and
+// returns the unconsumed remainder of rawTaf. Matching is confined to the
+// text before the next FM/BECMG/TEMPO/PROB30/PROB40 marker so that weather
+// and sky codes don't spuriously match letters inside those keywords.
+func parseTAFBody(rawTaf string, parsers map[string]*regexp.Regexp, group *TAFGroup) string {
+	rest := ""
+	if loc := parsers["nextgroup"].FindStringIndex(rawTaf); loc != nil {
+		rawTaf, rest = rawTaf[:loc[0]], rawTaf[loc[0]:]
+	}
+
+	if idx := parsers["wind"].FindStringSubmatchIndex(rawTaf); idx != nil {
+		windUnit := KT
+		if idx[10] != -1 && idx[11] != -1 {
+			windUnit = Unit(rawTaf[idx[10]:idx[11]])
+		}
+
+		wind := Wind{}
+		if idx[2] != -1 && idx[3] != -1 {
+			if rawTaf[idx[2]:idx[3]] == "VRB" {
+				wind.Vrb = true
+			} else if wdir, err := strconv.Atoi(rawTaf[idx[2]:idx[3]]); err == nil {
+				wind.Dir = wdir
+			}
+		}
+		if idx[4] != -1 && idx[5] != -1 {
+			if wspd, err := strconv.Atoi(rawTaf[idx[4]:idx[5]]); err == nil {
+				wind.Spd = WindSpeed{Value: float64(wspd), Unit: windUnit}
+			}
+		}
+		if idx[8] != -1 && idx[9] != -1 {
+			if gust, err := strconv.Atoi(rawTaf[idx[8]:idx[9]]); err == nil {
+				wind.Gust = WindSpeed{Value: float64(gust), Unit: windUnit}
+			}
+		}
+		group.Wind = wind
+		rawTaf = rawTaf[idx[1]:]
+	}
+
+	if idx := parsers["visibility"].FindStringSubmatchIndex(rawTaf); idx != nil {
+		switch {
+		case idx[4] != -1 && idx[5] != -1:
+			distStr := rawTaf[idx[4]:idx[5]]
+			qualifier := ""
+			if idx[6] != -1 && idx[7] != -1 {
+				qualifier = rawTaf[idx[6]:idx[7]]
+				distStr = distStr[len(qualifier):]
+			}
+			if vis, err := strconv.Atoi(distStr); err == nil {
+				group.Visibility = Visibility{Value: float64(vis), Unit: M, Qualifier: qualifier}
+			}
+		case idx[10] != -1 && idx[11] != -1:
+			qualifier := ""
+			if idx[12] != -1 && idx[13] != -1 {
+				qualifier = rawTaf[idx[12]:idx[13]]
+			}
+			if vis, err := strconv.Atoi(rawTaf[idx[14]:idx[15]]); err == nil {
+				unit := SM
+				if idx[16] != -1 && idx[17] != -1 {
+					unit = Unit(rawTaf[idx[16]:idx[17]])
+				}
+				group.Visibility = Visibility{Value: float64(vis), Unit: unit, Qualifier: qualifier}
+			}
+		}
+		rawTaf = rawTaf[idx[1]:]
+	}
+
+	if idxs := parsers["weather"].FindAllStringSubmatchIndex(rawTaf, -1); idxs != nil {
+		for _, idx := range idxs {
+			w := Weather{}
+			if idx[2] != -1 && idx[3] != -1 {
+				w.Intens = rawTaf[idx[2]:idx[3]]
+			}
+			if idx[6] != -1 && idx[7] != -1 {
+				w.Descr = rawTaf[idx[6]:idx[7]]
+			}
+			if idx[10] != -1 && idx[11] != -1 {
+				w.Precip = rawTaf[idx[10]:idx[11]]
+			}
+			if idx[16] != -1 && idx[17] != -1 {
+				w.Other = rawTaf[idx[16]:idx[17]]
+			}
+			group.Weather = append(group.Weather, w)
+		}
+		rawTaf = rawTaf[idxs[len(idxs)-1][1]:]
+	}
+
+	if idxs := parsers["sky"].FindAllStringSubmatchIndex(rawTaf, -1); idxs != nil {
+		for _, idx := range idxs {
+			sky := Sky{}
+			if idx[2] != -1 && idx[3] != -1 {
+				sky.Cover = rawTaf[idx[2]:idx[3]]
+			}
+			if idx[4] != -1 && idx[5] != -1 {
+				if height, err := strconv.Atoi(rawTaf[idx[4]:idx[5]]); err == nil {
+					sky.Height = height
+				}
+			}
+			if idx[6] != -1 && idx[7] != -1 {
+				sky.Cloud = rawTaf[idx[6]:idx[7]]
+			}
+			group.Sky = append(group.Sky, sky)
+		}
+		rawTaf = rawTaf[idxs[len(idxs)-1][1]:]
+	}
+
+	return rawTaf + rest
+}
+
+// Parse decodes a raw TAF report into t. rawDate anchors the report's
+// day-of-month fields to a month and year, the same way Metar.Parse does.
+func (t *TAF) Parse(rawTaf, rawDate string) error {
+	parsers := map[string]*regexp.Regexp{}
+	for key, value := range parserStrings {
+		parsers[key] = regexp.MustCompile(value)
+	}
+	for key, value := range tafParserStrings {
+		parsers[key] = regexp.MustCompile(value)
+	}
+
+	anchor, err := time.Parse(dateFormat, rawDate)
+	if err != nil {
+		return fmt.Errorf("Error parsing message time")
+	}
+
+	if idx := parsers["header"].FindStringSubmatchIndex(rawTaf); idx != nil {
+		rawTaf = rawTaf[idx[1]:]
+	}
+
+	idx := parsers["station"].FindStringSubmatchIndex(rawTaf)
+	if idx == nil {
+		return fmt.Errorf("Error parsing station identifier")
+	}
+	t.Station = rawTaf[idx[2]:idx[3]]
+	rawTaf = rawTaf[idx[1]:]
+
+	idx = parsers["time"].FindStringSubmatchIndex(rawTaf)
+	if idx == nil {
+		return fmt.Errorf("Error parsing taf issue time")
+	}
+	day, err := strconv.Atoi(rawTaf[idx[2]:idx[3]])
+	if err != nil {
+		return fmt.Errorf("Error converting day in taf")
+	}
+	hour, err := strconv.Atoi(rawTaf[idx[4]:idx[5]])
+	if err != nil {
+		return fmt.Errorf("Error converting hour in taf")
+	}
+	min, err := strconv.Atoi(rawTaf[idx[6]:idx[7]])
+	if err != nil {
+		return fmt.Errorf("Error converting minute in taf")
+	}
+	t.Issued = time.Date(anchor.Year(), anchor.Month(), day, hour, min, 0, 0, time.UTC)
+	rawTaf = rawTaf[idx[1]:]
+
+	idx = parsers["validity"].FindStringSubmatchIndex(rawTaf)
+	if idx == nil {
+		return fmt.Errorf("Error parsing taf validity period")
+	}
+	fday, err := strconv.Atoi(rawTaf[idx[2]:idx[3]])
+	if err != nil {
+		return fmt.Errorf("Error converting validity from day in taf")
+	}
+	fhour, err := strconv.Atoi(rawTaf[idx[4]:idx[5]])
+	if err != nil {
+		return fmt.Errorf("Error converting validity from hour in taf")
+	}
+	tday, err := strconv.Atoi(rawTaf[idx[6]:idx[7]])
+	if err != nil {
+		return fmt.Errorf("Error converting validity to day in taf")
+	}
+	thour, err := strconv.Atoi(rawTaf[idx[8]:idx[9]])
+	if err != nil {
+		return fmt.Errorf("Error converting validity to hour in taf")
+	}
+	t.ValidFrom = tafPeriod(t.Issued, fday, fhour)
+	t.ValidTo = tafPeriod(t.Issued, tday, thour)
+	rawTaf = rawTaf[idx[1]:]
+
+	group := TAFGroup{ValidFrom: t.ValidFrom, ValidTo: t.ValidTo}
+	rawTaf = parseTAFBody(rawTaf, parsers, &group)
+
+	for {
+		// Tokens parseTAFBody didn't recognise (turbulence, icing, wind
+		// shear, QNH, ...) can be left sitting before the next change
+		// marker; skip forward to it so the anchored fm/change matches
+		// below don't fail and silently truncate the report.
+		if loc := parsers["nextgroup"].FindStringIndex(rawTaf); loc != nil {
+			rawTaf = rawTaf[loc[0]:]
+		} else {
+			break
+		}
+
+		if idx = parsers["fm"].FindStringSubmatchIndex(rawTaf); idx != nil {
+			t.Groups = append(t.Groups, group)
+			day, err := strconv.Atoi(rawTaf[idx[2]:idx[3]])
+			if err != nil {
+				return fmt.Errorf("Error converting FM day in taf")
+			}
+			hour, err := strconv.Atoi(rawTaf[idx[4]:idx[5]])
+			if err != nil {
+				return fmt.Errorf("Error converting FM hour in taf")
+			}
+			from := tafPeriod(t.Issued, day, hour)
+			group = TAFGroup{Change: "FM", ValidFrom: from, ValidTo: t.ValidTo}
+			rawTaf = rawTaf[idx[1]:]
+			rawTaf = parseTAFBody(rawTaf, parsers, &group)
+			continue
+		}
+
+		if idx = parsers["change"].FindStringSubmatchIndex(rawTaf); idx != nil {
+			t.Groups = append(t.Groups, group)
+			change := rawTaf[idx[2]:idx[3]]
+			fday, err := strconv.Atoi(rawTaf[idx[6]:idx[7]])
+			if err != nil {
+				return fmt.Errorf("Error converting %s from day in taf", change)
+			}
+			fhour, err := strconv.Atoi(rawTaf[idx[8]:idx[9]])
+			if err != nil {
+				return fmt.Errorf("Error converting %s from hour in taf", change)
+			}
+			tday, err := strconv.Atoi(rawTaf[idx[10]:idx[11]])
+			if err != nil {
+				return fmt.Errorf("Error converting %s to day in taf", change)
+			}
+			thour, err := strconv.Atoi(rawTaf[idx[12]:idx[13]])
+			if err != nil {
+				return fmt.Errorf("Error converting %s to hour in taf", change)
+			}
+			group = TAFGroup{
+				Change:    change,
+				ValidFrom: tafPeriod(t.Issued, fday, fhour),
+				ValidTo:   tafPeriod(t.Issued, tday, thour),
+			}
+			rawTaf = rawTaf[idx[1]:]
+			rawTaf = parseTAFBody(rawTaf, parsers, &group)
+			continue
+		}
+
+		break
+	}
+	t.Groups = append(t.Groups, group)
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package hello
+
+import "testing"
+
+func TestTAFParseSkipsUnrecognisedTokens(t *testing.T) {
+	raw := "TAF KBOS 271730Z 2718/2818 14008KT 9999 FEW030 510009 " +
+		"FM271800 18012KT 9999 SCT025 " +
+		"BECMG 2720/2722 21015G25KT"
+	taf := &TAF{}
+	if err := taf.Parse(raw, "2016/07/27 17:30"); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(taf.Groups) != 3 {
+		t.Fatalf("got %d groups, want 3: %+v", len(taf.Groups), taf.Groups)
+	}
+}
@@ -0,0 +1,223 @@
+package hello
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Unit identifies the native unit a parsed value was reported in.
+type Unit string
+
+const (
+	KT  Unit = "KT"  // knots
+	KMH Unit = "KMH" // kilometres per hour
+	MPS Unit = "MPS" // metres per second
+	MPH Unit = "MPH" // miles per hour
+
+	M  Unit = "M"  // metres
+	KM Unit = "KM" // kilometres
+	SM Unit = "SM" // statute miles
+
+	C Unit = "C" // degrees Celsius
+	F Unit = "F" // degrees Fahrenheit
+
+	HPA  Unit = "HPA"  // hectopascals
+	INHG Unit = "INHG" // inches of mercury
+)
+
+// UnitSystem selects the set of units Metar.InUnits converts a report to.
+type UnitSystem string
+
+const (
+	Metric   UnitSystem = "metric"
+	Imperial UnitSystem = "imperial"
+	Aviation UnitSystem = "aviation"
+)
+
+// WindSpeed is a wind speed value tagged with its native unit.
+type WindSpeed struct {
+	Value float64
+	Unit  Unit
+}
+
+func (s WindSpeed) mps() float64 {
+	switch s.Unit {
+	case KMH:
+		return s.Value / 3.6
+	case MPH:
+		return s.Value * 0.44704
+	case MPS:
+		return s.Value
+	default: // KT
+		return s.Value * 0.514444
+	}
+}
+
+// In returns s converted to unit.
+func (s WindSpeed) In(unit Unit) WindSpeed {
+	mps := s.mps()
+	var v float64
+	switch unit {
+	case KMH:
+		v = mps * 3.6
+	case MPH:
+		v = mps / 0.44704
+	case MPS:
+		v = mps
+	default: // KT
+		v = mps / 0.514444
+	}
+	return WindSpeed{Value: v, Unit: unit}
+}
+
+// MarshalJSON emits the speed normalised to metres per second alongside
+// a human readable display string in its native unit.
+func (s WindSpeed) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ValueSI float64 `json:"value_si"`
+		UnitSI  string  `json:"unit_si"`
+		Display string  `json:"display"`
+	}{ValueSI: s.mps(), UnitSI: "m/s", Display: fmt.Sprintf("%.0f%s", s.Value, s.Unit)})
+}
+
+// Visibility is a visibility value tagged with its native unit.
+type Visibility struct {
+	Value float64
+	Unit  Unit
+
+	// Qualifier is "P" (greater than Value) or "M" (less than Value) when
+	// the report prefixed the visibility that way, empty otherwise.
+	Qualifier string
+}
+
+func (v Visibility) metres() float64 {
+	switch v.Unit {
+	case KM:
+		return v.Value * 1000
+	case SM:
+		return v.Value * 1609.34
+	default: // M
+		return v.Value
+	}
+}
+
+// In returns v converted to unit.
+func (v Visibility) In(unit Unit) Visibility {
+	m := v.metres()
+	var val float64
+	switch unit {
+	case KM:
+		val = m / 1000
+	case SM:
+		val = m / 1609.34
+	default: // M
+		val = m
+	}
+	return Visibility{Value: val, Unit: unit, Qualifier: v.Qualifier}
+}
+
+// MarshalJSON emits the visibility normalised to metres alongside a
+// human readable display string in its native unit.
+func (v Visibility) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ValueSI float64 `json:"value_si"`
+		UnitSI  string  `json:"unit_si"`
+		Display string  `json:"display"`
+	}{ValueSI: v.metres(), UnitSI: "m", Display: fmt.Sprintf("%s%v%s", v.Qualifier, v.Value, v.Unit)})
+}
+
+// Temperature is a temperature value tagged with its native unit.
+type Temperature struct {
+	Value float64
+	Unit  Unit
+}
+
+func (t Temperature) celsius() float64 {
+	if t.Unit == F {
+		return (t.Value - 32) * 5 / 9
+	}
+	return t.Value
+}
+
+// In returns t converted to unit.
+func (t Temperature) In(unit Unit) Temperature {
+	c := t.celsius()
+	if unit == F {
+		return Temperature{Value: c*9/5 + 32, Unit: F}
+	}
+	return Temperature{Value: c, Unit: C}
+}
+
+// MarshalJSON emits the temperature normalised to Celsius alongside a
+// human readable display string in its native unit.
+func (t Temperature) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ValueSI float64 `json:"value_si"`
+		UnitSI  string  `json:"unit_si"`
+		Display string  `json:"display"`
+	}{ValueSI: t.celsius(), UnitSI: "C", Display: fmt.Sprintf("%v°%s", t.Value, t.Unit)})
+}
+
+// Pressure is a barometric pressure value tagged with its native unit.
+type Pressure struct {
+	Value float64
+	Unit  Unit
+}
+
+func (p Pressure) hpa() float64 {
+	if p.Unit == INHG {
+		return p.Value * 33.8639
+	}
+	return p.Value
+}
+
+// In returns p converted to unit.
+func (p Pressure) In(unit Unit) Pressure {
+	hpa := p.hpa()
+	if unit == INHG {
+		return Pressure{Value: hpa / 33.8639, Unit: INHG}
+	}
+	return Pressure{Value: hpa, Unit: HPA}
+}
+
+// MarshalJSON emits the pressure normalised to hectopascals alongside a
+// human readable display string in its native unit.
+func (p Pressure) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ValueSI float64 `json:"value_si"`
+		UnitSI  string  `json:"unit_si"`
+		Display string  `json:"display"`
+	}{ValueSI: p.hpa(), UnitSI: "hPa", Display: fmt.Sprintf("%v%s", p.Value, p.Unit)})
+}
+
+// InUnits returns a copy of m with Wind.Spd, Visibility, Temp, DewPt and
+// Pressure converted to the units conventionally used by system: metric
+// (km/h, km, °C, hPa), imperial (mph, statute miles, °F, hPa) or aviation
+// (kt, statute miles, °F, inHg).
+func (m Metar) InUnits(system UnitSystem) Metar {
+	out := m
+	switch system {
+	case Imperial:
+		out.Wind.Spd = m.Wind.Spd.In(MPH)
+		out.Wind.Gust = m.Wind.Gust.In(MPH)
+		out.Visibility = m.Visibility.In(SM)
+		out.Temp = m.Temp.In(F)
+		out.DewPt = m.DewPt.In(F)
+		out.Pressure = m.Pressure.In(HPA)
+	case Aviation:
+		out.Wind.Spd = m.Wind.Spd.In(KT)
+		out.Wind.Gust = m.Wind.Gust.In(KT)
+		out.Visibility = m.Visibility.In(SM)
+		out.Temp = m.Temp.In(F)
+		out.DewPt = m.DewPt.In(F)
+		out.Pressure = m.Pressure.In(INHG)
+	default: // Metric
+		out.Wind.Spd = m.Wind.Spd.In(KMH)
+		out.Wind.Gust = m.Wind.Gust.In(KMH)
+		out.Visibility = m.Visibility.In(KM)
+		out.Temp = m.Temp.In(C)
+		out.DewPt = m.DewPt.In(C)
+		out.Pressure = m.Pressure.In(HPA)
+	}
+	return out
+}